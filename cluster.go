@@ -0,0 +1,101 @@
+//Copyright 2013 Vastech SA (PTY) LTD
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS,
+//WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//See the License for the specific language governing permissions and
+//limitations under the License.
+
+package gographviz
+
+import "strings"
+
+//GroupBy partitions every node of this graph by the value of attrKey and
+//returns a new Graph in which every distinct value becomes a subgraph
+//named "cluster_" plus the value passed through sanitizeID, so Graphviz
+//renders it as a visible box; values that sanitize to the same id share
+//one subgraph. This is the pattern dep's graphviz output uses to cluster
+//versions under project boxes. This graph's existing subgraphs are not
+//copied, so nodes without attrKey set end up at the top level. Edges are
+//copied unchanged, so inter-cluster edges stay at the top level and dot
+//lays them out correctly.
+func (this *Graph) GroupBy(attrKey string) *Graph {
+	return this.GroupByTemplate(attrKey, nil)
+}
+
+//GroupByTemplate is GroupBy with a template of attrs (e.g. {"style":
+//"filled"}) applied to every generated subgraph, in addition to a "label"
+//attr set to the partition's value.
+func (this *Graph) GroupByTemplate(attrKey string, template map[string]string) *Graph {
+	out := NewGraph()
+	out.SetName(this.Name)
+	out.SetDir(this.Directed)
+	out.SetStrict(this.Strict)
+	for k, v := range this.Attrs {
+		out.AddAttr(out.Name, k, v)
+	}
+
+	clusters := make(map[string]string)
+	for name, node := range this.Nodes.Lookup {
+		parent := out.Name
+		if value, ok := node.Attrs[attrKey]; ok {
+			cluster, seen := clusters[value]
+			if !seen {
+				cluster = "cluster_" + sanitizeID(value)
+				clusters[value] = cluster
+				attrs := map[string]string{"label": value}
+				for k, v := range template {
+					attrs[k] = v
+				}
+				out.AddSubGraph(out.Name, cluster, attrs)
+			}
+			parent = cluster
+		}
+		out.AddNode(parent, name, copyAttrs(node.Attrs))
+	}
+
+	for src, dsts := range this.Edges.SrcToDsts {
+		for dst, e := range dsts {
+			out.AddPortEdge(src, e.SrcPort, dst, e.DstPort, e.Dir, copyAttrs(e.Attrs))
+		}
+	}
+
+	return out
+}
+
+//sanitizeID turns value into a string that is always legal as a plain
+//(unquoted) DOT identifier, by replacing every character outside
+//[A-Za-z0-9_] with "_". SafeName alone only guards against the DOT
+//keywords, so a value like "1.0.0" (a very ordinary thing to cluster
+//versions by) would otherwise produce an illegal subgraph id such as
+//cluster_1.0.0.
+func sanitizeID(value string) string {
+	var b strings.Builder
+	for _, r := range value {
+		switch {
+		case r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9'):
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+	}
+	return SafeName(b.String())
+}
+
+//copyAttrs returns a shallow copy of attrs, so a Graph built from another
+//(e.g. by GroupBy) does not alias its attribute maps: applyQuoting and
+//applyCycleAttrs mutate these maps in place during a render, and without a
+//copy that mutation on one graph would corrupt the other.
+func copyAttrs(attrs map[string]string) map[string]string {
+	cp := make(map[string]string, len(attrs))
+	for k, v := range attrs {
+		cp[k] = v
+	}
+	return cp
+}
@@ -0,0 +1,94 @@
+//Copyright 2013 Vastech SA (PTY) LTD
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS,
+//WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//See the License for the specific language governing permissions and
+//limitations under the License.
+
+package gographviz
+
+import "testing"
+
+func TestGroupByPartitionsNodesIntoClusters(t *testing.T) {
+	g := NewGraph()
+	g.SetName("g")
+	g.SetDir(true)
+	g.AddNode("g", "a", map[string]string{"project": "foo"})
+	g.AddNode("g", "b", map[string]string{"project": "foo"})
+	g.AddNode("g", "c", map[string]string{"project": "bar"})
+	g.AddNode("g", "d", nil)
+	g.AddEdge("a", "b", true, nil)
+	g.AddEdge("a", "c", true, nil)
+
+	out := g.GroupBy("project")
+
+	if !out.IsSubGraph("cluster_foo") || !out.IsSubGraph("cluster_bar") {
+		t.Fatalf("expected a cluster per distinct project value")
+	}
+	if !out.IsNode("d") {
+		t.Fatalf("expected node without the attr to stay at the top level")
+	}
+	if got := out.SubGraphs.SubGraphs["cluster_foo"].Attrs["label"]; got != "foo" {
+		t.Fatalf("expected cluster_foo to be labeled foo, got %q", got)
+	}
+	if _, ok := out.Edges.SrcToDsts["a"]["b"]; !ok {
+		t.Fatalf("expected the a->b edge to be copied")
+	}
+	if _, ok := out.Edges.SrcToDsts["a"]["c"]; !ok {
+		t.Fatalf("expected the cross-cluster a->c edge to be copied")
+	}
+}
+
+func TestGroupByTemplateAppliesTemplateAttrs(t *testing.T) {
+	g := NewGraph()
+	g.SetName("g")
+	g.SetDir(true)
+	g.AddNode("g", "a", map[string]string{"project": "foo"})
+
+	out := g.GroupByTemplate("project", map[string]string{"style": "filled"})
+
+	attrs := out.SubGraphs.SubGraphs["cluster_foo"].Attrs
+	if attrs["style"] != "filled" || attrs["label"] != "foo" {
+		t.Fatalf("expected template attrs plus label on the cluster, got %v", attrs)
+	}
+}
+
+func TestGroupBySanitizesClusterID(t *testing.T) {
+	g := NewGraph()
+	g.SetName("g")
+	g.SetDir(true)
+	g.AddNode("g", "a", map[string]string{"version": "1.0.0"})
+
+	out := g.GroupBy("version")
+
+	if !out.IsSubGraph("cluster_1_0_0") {
+		t.Fatalf("expected the illegal characters in the value to be sanitized in the cluster id")
+	}
+}
+
+func TestGroupByDoesNotAliasSourceAttrs(t *testing.T) {
+	g := NewGraph()
+	g.SetName("g")
+	g.SetDir(true)
+	srcAttrs := map[string]string{"color": "blue"}
+	g.AddNode("g", "a", srcAttrs)
+	g.AddEdge("a", "a", true, map[string]string{"style": "dashed"})
+
+	out := g.GroupBy("project")
+	out.Nodes.Lookup["a"].Attrs["color"] = "red"
+	out.Edges.SrcToDsts["a"]["a"].Attrs["style"] = "solid"
+
+	if srcAttrs["color"] != "blue" {
+		t.Fatalf("expected mutating the copy to leave the source node attrs untouched, got %v", srcAttrs)
+	}
+	if g.Edges.SrcToDsts["a"]["a"].Attrs["style"] != "dashed" {
+		t.Fatalf("expected mutating the copy to leave the source edge attrs untouched, got %v", g.Edges.SrcToDsts["a"]["a"].Attrs)
+	}
+}
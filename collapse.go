@@ -0,0 +1,196 @@
+//Copyright 2013 Vastech SA (PTY) LTD
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS,
+//WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//See the License for the specific language governing permissions and
+//limitations under the License.
+
+package gographviz
+
+import (
+	"fmt"
+
+	"github.com/firegoblin/gographviz/ast"
+)
+
+//WriteOpts controls optional rendering behaviour of WriteAstWithOpts and
+//WriteTo beyond the graph's own data.
+type WriteOpts struct {
+	//MaxDepth collapses any subgraph nested deeper than MaxDepth levels
+	//below the root graph into a single synthetic node. Zero means no
+	//limit. This is the same capability Terraform exposes via
+	//-module-depth on `terraform graph`.
+	MaxDepth int
+
+	//CollapseBelow, if set, collapses a subgraph regardless of depth when
+	//it returns true for that subgraph's name.
+	CollapseBelow func(subgraph string) bool
+
+	//MergeEdgeAttrs resolves the attrs of two edges that become parallel
+	//as a result of a collapse. Defaults to last-write-wins.
+	MergeEdgeAttrs func(a, b map[string]string) map[string]string
+}
+
+func (o *WriteOpts) shouldCollapse(name string, depth int) bool {
+	if o == nil {
+		return false
+	}
+	if o.MaxDepth > 0 && depth > o.MaxDepth {
+		return true
+	}
+	return o.CollapseBelow != nil && o.CollapseBelow(name)
+}
+
+func (o *WriteOpts) mergeEdgeAttrs(a, b map[string]string) map[string]string {
+	if o != nil && o.MergeEdgeAttrs != nil {
+		return o.MergeEdgeAttrs(a, b)
+	}
+	merged := make(map[string]string, len(a)+len(b))
+	for k, v := range a {
+		merged[k] = v
+	}
+	for k, v := range b {
+		merged[k] = v
+	}
+	return merged
+}
+
+//collapsedNodeName derives a stable synthetic name for a collapsed
+//subgraph, from the subgraph's own name, so repeated renders of an
+//unchanged graph stay diffable.
+func collapsedNodeName(subgraph string) string {
+	return fmt.Sprintf("collapsed_%s", SafeName(subgraph))
+}
+
+//WriteAstWithOpts is WriteAst extended with rendering options: beyond
+//opts.MaxDepth, or where opts.CollapseBelow returns true, an entire
+//subgraph is replaced by a single synthetic node whose in/out edges are
+//the deduplicated union of the edges crossing the collapsed boundary,
+//with parallel-edge attrs merged via opts.MergeEdgeAttrs.
+func (g *Graph) WriteAstWithOpts(opts *WriteOpts) *ast.Graph {
+	if opts == nil || (opts.MaxDepth == 0 && opts.CollapseBelow == nil) {
+		return g.WriteAst()
+	}
+	return g.collapse(opts).WriteAst()
+}
+
+//collapse returns a new Graph equivalent to this one with every subgraph
+//opts selects replaced by a single synthetic node, per WriteAstWithOpts.
+func (this *Graph) collapse(opts *WriteOpts) *Graph {
+	out := NewGraph()
+	out.SetName(this.Name)
+	out.SetDir(this.Directed)
+	out.SetStrict(this.Strict)
+	for k, v := range this.Attrs {
+		out.AddAttr(out.Name, k, v)
+	}
+
+	//owner maps every node and subgraph name in this graph to the name it
+	//should be addressed as in out: itself, or the synthetic node its
+	//collapsed subgraph was replaced with. Subgraphs never nest - a
+	//subgraph is never itself the parent of another subgraph, only of
+	//nodes - so the whole graph is exactly two levels deep: the root, and
+	//each of its subgraphs.
+	owner := make(map[string]string)
+
+	collapseInto := func(outParent, name string, members []string, depth int) {
+		synth := collapsedNodeName(name)
+		out.AddNode(outParent, synth, map[string]string{"label": name, "shape": "box3d"})
+		owner[name] = synth
+		for _, member := range members {
+			owner[member] = synth
+		}
+	}
+
+	if opts.shouldCollapse(this.Name, 0) {
+		var members []string
+		for _, name := range this.Relations.SortedChildren(this.Name) {
+			members = append(members, name)
+		}
+		for _, sg := range this.SubGraphs.Sorted() {
+			members = append(members, sg.Name)
+			members = append(members, this.Relations.SortedChildren(sg.Name)...)
+		}
+		collapseInto(this.Name, this.Name, members, 0)
+	} else {
+		for _, name := range this.Relations.SortedChildren(this.Name) {
+			out.AddNode(this.Name, name, this.Nodes.Lookup[name].Attrs)
+			owner[name] = name
+		}
+
+		for _, sg := range this.SubGraphs.Sorted() {
+			children := this.Relations.SortedChildren(sg.Name)
+			if opts.shouldCollapse(sg.Name, 1) {
+				collapseInto(this.Name, sg.Name, children, 1)
+				continue
+			}
+
+			attrs := map[string]string{}
+			for k, v := range sg.Attrs {
+				attrs[k] = v
+			}
+			out.AddSubGraph(this.Name, sg.Name, attrs)
+			owner[sg.Name] = sg.Name
+			for _, child := range children {
+				out.AddNode(sg.Name, child, this.Nodes.Lookup[child].Attrs)
+				owner[child] = child
+			}
+		}
+	}
+
+	type edgeKey struct {
+		src, dst, srcPort, dstPort string
+		dir                        bool
+	}
+	merged := make(map[edgeKey]map[string]string)
+	var order []edgeKey
+	for src, dsts := range this.Edges.SrcToDsts {
+		for dst, e := range dsts {
+			s, ok := owner[src]
+			if !ok {
+				s = src
+			}
+			d, ok := owner[dst]
+			if !ok {
+				d = dst
+			}
+			if s == d {
+				//both endpoints collapsed into the same synthetic
+				//node: this edge no longer crosses a boundary.
+				continue
+			}
+
+			var key edgeKey
+			if s != src || d != dst {
+				//one of the endpoints was replaced by a synthetic
+				//collapsed node: ports are meaningless here, so drop
+				//them rather than carry a stale port onto a node
+				//that never had one.
+				key = edgeKey{src: s, dst: d, dir: e.Dir}
+			} else {
+				//this edge never crossed a collapsed boundary - keep
+				//its ports intact.
+				key = edgeKey{src: s, dst: d, srcPort: e.SrcPort, dstPort: e.DstPort, dir: e.Dir}
+			}
+
+			if existing, ok := merged[key]; ok {
+				merged[key] = opts.mergeEdgeAttrs(existing, e.Attrs)
+			} else {
+				merged[key] = e.Attrs
+				order = append(order, key)
+			}
+		}
+	}
+	for _, key := range order {
+		out.AddPortEdge(key.src, key.srcPort, key.dst, key.dstPort, key.dir, merged[key])
+	}
+
+	return out
+}
@@ -0,0 +1,104 @@
+//Copyright 2013 Vastech SA (PTY) LTD
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS,
+//WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//See the License for the specific language governing permissions and
+//limitations under the License.
+
+package gographviz
+
+import "testing"
+
+func buildCollapseTestGraph() *Graph {
+	g := NewGraph()
+	g.SetName("g")
+	g.SetDir(true)
+	g.AddNode("g", "a", nil)
+	g.AddNode("g", "b", nil)
+	g.AddSubGraph("g", "sub", nil)
+	g.AddNode("sub", "x", nil)
+	g.AddNode("sub", "y", nil)
+
+	//two edges crossing into the collapsed subgraph: these should be
+	//merged into one and lose their ports.
+	g.AddPortEdge("a", "p1", "x", "", true, map[string]string{"color": "blue"})
+	g.AddPortEdge("a", "", "y", "", true, map[string]string{"color": "red"})
+
+	//an edge that never touches the collapsed subgraph: its ports must
+	//survive untouched.
+	g.AddPortEdge("a", "out", "b", "in", true, map[string]string{"style": "dashed"})
+
+	return g
+}
+
+func TestCollapseMergesCrossingEdgesAndDropsPorts(t *testing.T) {
+	g := buildCollapseTestGraph()
+	opts := &WriteOpts{CollapseBelow: func(name string) bool { return name == "sub" }}
+
+	out := g.collapse(opts)
+
+	e, ok := out.Edges.SrcToDsts["a"]["collapsed_sub"]
+	if !ok {
+		t.Fatalf("expected a merged edge a->collapsed_sub")
+	}
+	if e.SrcPort != "" || e.DstPort != "" {
+		t.Fatalf("expected ports to be dropped on a collapsed edge, got %q/%q", e.SrcPort, e.DstPort)
+	}
+	//default merge is last-write-wins: whichever of the two original
+	//edges was visited second wins, but the result must be one of them.
+	if e.Attrs["color"] != "blue" && e.Attrs["color"] != "red" {
+		t.Fatalf("expected merged attrs to come from one of the source edges, got %v", e.Attrs)
+	}
+}
+
+func TestCollapsePreservesPortsOnUntouchedEdges(t *testing.T) {
+	g := buildCollapseTestGraph()
+	opts := &WriteOpts{CollapseBelow: func(name string) bool { return name == "sub" }}
+
+	out := g.collapse(opts)
+
+	e, ok := out.Edges.SrcToDsts["a"]["b"]
+	if !ok {
+		t.Fatalf("expected an a->b edge")
+	}
+	if e.SrcPort != "out" || e.DstPort != "in" {
+		t.Fatalf("expected a->b to keep its ports, got %q/%q", e.SrcPort, e.DstPort)
+	}
+}
+
+func TestCollapseCustomMergeFunc(t *testing.T) {
+	g := buildCollapseTestGraph()
+	opts := &WriteOpts{
+		CollapseBelow: func(name string) bool { return name == "sub" },
+		MergeEdgeAttrs: func(a, b map[string]string) map[string]string {
+			return map[string]string{"color": "merged"}
+		},
+	}
+
+	out := g.collapse(opts)
+
+	e, ok := out.Edges.SrcToDsts["a"]["collapsed_sub"]
+	if !ok || e.Attrs["color"] != "merged" {
+		t.Fatalf("expected the custom merge func to run, got %v", e)
+	}
+}
+
+func TestCollapseStableSyntheticName(t *testing.T) {
+	g1 := buildCollapseTestGraph()
+	g2 := buildCollapseTestGraph()
+	opts := &WriteOpts{CollapseBelow: func(name string) bool { return name == "sub" }}
+
+	out1 := g1.collapse(opts)
+	out2 := g2.collapse(opts)
+
+	if !out1.IsNode("collapsed_sub") || !out2.IsNode("collapsed_sub") {
+		t.Fatalf("expected the synthetic node name to be derived from the subgraph name on both renders")
+	}
+}
@@ -0,0 +1,186 @@
+//Copyright 2013 Vastech SA (PTY) LTD
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS,
+//WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//See the License for the specific language governing permissions and
+//limitations under the License.
+
+package gographviz
+
+//FindCycles returns the graph's cycles, each as a list of node names: every
+//strongly connected component of size greater than one, plus any node with
+//a self-loop. It builds an adjacency list from Edges.SrcToDsts and runs
+//Tarjan's algorithm with an explicit stack, so it does not blow the call
+//stack on graphs with long dependency chains.
+func (this *Graph) FindCycles() [][]string {
+	adj := make(map[string][]string)
+	for src, dsts := range this.Edges.SrcToDsts {
+		for dst := range dsts {
+			adj[src] = append(adj[src], dst)
+		}
+	}
+
+	t := &tarjan{
+		adj:     adj,
+		index:   make(map[string]int),
+		lowlink: make(map[string]int),
+		onStack: make(map[string]bool),
+	}
+	for name := range this.Nodes.Lookup {
+		if _, visited := t.index[name]; !visited {
+			t.strongconnect(name)
+		}
+	}
+
+	cycles := make([][]string, 0, len(t.sccs))
+	for _, scc := range t.sccs {
+		if len(scc) > 1 {
+			cycles = append(cycles, scc)
+			continue
+		}
+		name := scc[0]
+		for _, dst := range adj[name] {
+			if dst == name {
+				cycles = append(cycles, scc)
+				break
+			}
+		}
+	}
+	return cycles
+}
+
+//DrawCycles enables cycle highlighting in the DOT produced by WriteAst:
+//every edge whose source and destination both fall in the same cycle (as
+//found by FindCycles) is overlaid with attrs for that render. This mirrors
+//Terraform's -draw-cycles graph mode.
+func (this *Graph) DrawCycles(attrs map[string]string) {
+	this.drawCycles = true
+	this.cycleAttrs = attrs
+}
+
+//applyCycleAttrs temporarily merges the DrawCycles overlay into every edge
+//whose endpoints share a cycle, for the duration of a single WriteAst call,
+//and returns a func that restores the original attrs. Cycle membership is
+//recomputed from FindCycles on every call rather than cached on the Graph,
+//since callers are free to add or remove nodes/edges between renders and a
+//cached result would silently go stale.
+func (this *Graph) applyCycleAttrs() func() {
+	if !this.drawCycles {
+		return func() {}
+	}
+
+	inSameCycle := make(map[string]bool)
+	for _, cycle := range this.FindCycles() {
+		for _, a := range cycle {
+			for _, b := range cycle {
+				inSameCycle[a+"\x00"+b] = true
+			}
+		}
+	}
+
+	type touched struct {
+		edge  *Edge
+		attrs map[string]string
+	}
+	var restore []touched
+	for src, dsts := range this.Edges.SrcToDsts {
+		for dst, e := range dsts {
+			if !inSameCycle[src+"\x00"+dst] {
+				continue
+			}
+			restore = append(restore, touched{e, e.Attrs})
+			merged := make(map[string]string, len(e.Attrs)+len(this.cycleAttrs))
+			for k, v := range e.Attrs {
+				merged[k] = v
+			}
+			for k, v := range this.cycleAttrs {
+				merged[k] = v
+			}
+			e.Attrs = merged
+		}
+	}
+
+	return func() {
+		for _, r := range restore {
+			r.edge.Attrs = r.attrs
+		}
+	}
+}
+
+//tarjan is the state for one run of Tarjan's strongly-connected-components
+//algorithm, walked iteratively so deep graphs don't recurse.
+type tarjan struct {
+	adj     map[string][]string
+	index   map[string]int
+	lowlink map[string]int
+	onStack map[string]bool
+	stack   []string
+	next    int
+	sccs    [][]string
+}
+
+//frame is a single stack frame of the iterative walk: the node being
+//visited and how far through its neighbour list strongconnect has got.
+type frame struct {
+	node string
+	i    int
+}
+
+func (t *tarjan) strongconnect(start string) {
+	work := []*frame{t.visit(start)}
+
+	for len(work) > 0 {
+		f := work[len(work)-1]
+
+		if f.i < len(t.adj[f.node]) {
+			next := t.adj[f.node][f.i]
+			f.i++
+			if _, visited := t.index[next]; !visited {
+				work = append(work, t.visit(next))
+				continue
+			}
+			if t.onStack[next] && t.index[next] < t.lowlink[f.node] {
+				t.lowlink[f.node] = t.index[next]
+			}
+			continue
+		}
+
+		work = work[:len(work)-1]
+		if len(work) > 0 {
+			parent := work[len(work)-1]
+			if t.lowlink[f.node] < t.lowlink[parent.node] {
+				t.lowlink[parent.node] = t.lowlink[f.node]
+			}
+		}
+
+		if t.lowlink[f.node] == t.index[f.node] {
+			var scc []string
+			for {
+				n := t.stack[len(t.stack)-1]
+				t.stack = t.stack[:len(t.stack)-1]
+				t.onStack[n] = false
+				scc = append(scc, n)
+				if n == f.node {
+					break
+				}
+			}
+			t.sccs = append(t.sccs, scc)
+		}
+	}
+}
+
+func (t *tarjan) visit(node string) *frame {
+	t.index[node] = t.next
+	t.lowlink[node] = t.next
+	t.next++
+	t.stack = append(t.stack, node)
+	t.onStack[node] = true
+	return &frame{node: node}
+}
@@ -0,0 +1,112 @@
+//Copyright 2013 Vastech SA (PTY) LTD
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS,
+//WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//See the License for the specific language governing permissions and
+//limitations under the License.
+
+package gographviz
+
+import "testing"
+
+func cycleSet(cycles [][]string) map[string]bool {
+	set := make(map[string]bool)
+	for _, cycle := range cycles {
+		members := make(map[string]bool)
+		for _, n := range cycle {
+			members[n] = true
+		}
+		key := ""
+		for _, n := range []string{"a", "b", "c", "d"} {
+			if members[n] {
+				key += n
+			}
+		}
+		set[key] = true
+	}
+	return set
+}
+
+func TestFindCyclesNoCycle(t *testing.T) {
+	g := NewGraph()
+	g.SetName("g")
+	g.SetDir(true)
+	g.AddNode("g", "a", nil)
+	g.AddNode("g", "b", nil)
+	g.AddNode("g", "c", nil)
+	g.AddEdge("a", "b", true, nil)
+	g.AddEdge("b", "c", true, nil)
+
+	if cycles := g.FindCycles(); len(cycles) != 0 {
+		t.Fatalf("expected no cycles in a DAG, got %v", cycles)
+	}
+}
+
+func TestFindCyclesSimpleCycle(t *testing.T) {
+	g := NewGraph()
+	g.SetName("g")
+	g.SetDir(true)
+	g.AddNode("g", "a", nil)
+	g.AddNode("g", "b", nil)
+	g.AddNode("g", "c", nil)
+	g.AddEdge("a", "b", true, nil)
+	g.AddEdge("b", "c", true, nil)
+	g.AddEdge("c", "a", true, nil)
+
+	cycles := g.FindCycles()
+	if len(cycles) != 1 || len(cycles[0]) != 3 {
+		t.Fatalf("expected a single 3-node cycle, got %v", cycles)
+	}
+	if !cycleSet(cycles)["abc"] {
+		t.Fatalf("expected cycle to contain a, b and c, got %v", cycles)
+	}
+}
+
+func TestFindCyclesSelfLoop(t *testing.T) {
+	g := NewGraph()
+	g.SetName("g")
+	g.SetDir(true)
+	g.AddNode("g", "a", nil)
+	g.AddEdge("a", "a", true, nil)
+
+	cycles := g.FindCycles()
+	if len(cycles) != 1 || len(cycles[0]) != 1 || cycles[0][0] != "a" {
+		t.Fatalf("expected a single self-loop cycle on a, got %v", cycles)
+	}
+}
+
+func TestDrawCyclesRecomputesAfterMutation(t *testing.T) {
+	g := NewGraph()
+	g.SetName("g")
+	g.SetDir(true)
+	g.AddNode("g", "a", nil)
+	g.AddNode("g", "b", nil)
+	g.AddEdge("a", "b", true, nil)
+	g.DrawCycles(map[string]string{"color": "red"})
+
+	//no cycle yet: the overlay must not touch the a->b edge.
+	restore := g.applyCycleAttrs()
+	edge := g.Edges.SrcToDsts["a"]["b"]
+	if _, ok := edge.Attrs["color"]; ok {
+		t.Fatalf("did not expect a color overlay before a cycle exists")
+	}
+	restore()
+
+	//closing the cycle after DrawCycles was called must still be picked
+	//up on the next render, since cycle membership is recomputed on every
+	//render.
+	g.AddEdge("b", "a", true, nil)
+	restore = g.applyCycleAttrs()
+	edge = g.Edges.SrcToDsts["a"]["b"]
+	if edge.Attrs["color"] != "red" {
+		t.Fatalf("expected the overlay to apply once a, b form a cycle, got %v", edge.Attrs)
+	}
+	restore()
+}
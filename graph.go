@@ -15,6 +15,7 @@
 package gographviz
 
 import "github.com/firegoblin/gographviz/ast"
+import "bytes"
 import "strings"
 import "fmt"
 import "os"
@@ -47,6 +48,11 @@ type Graph struct {
 	Edges     *Edges
 	SubGraphs *SubGraphs
 	Relations *Relations
+
+	//drawCycles and cycleAttrs back DrawCycles; see cycles.go. Cycle
+	//membership itself is never cached on the Graph - see applyCycleAttrs.
+	drawCycles bool
+	cycleAttrs map[string]string
 }
 
 //Creates a new empty graph, ready to be populated.
@@ -138,8 +144,39 @@ func (this *Graph) AddNodeInterface(parentGraph string, node NodeInterface) {
 }
 
 func (this *Graph) AddGraphableNode(parentGraph string, node GraphableNode) {
-	this.AddNodeInterface(parentGraph, node)
-	this.AddEdgesInterface(node)
+	skip := false
+	if renderer, ok := node.(NodeRenderer); ok {
+		label, attrs, shape := renderer.DOTNode(node.Name())
+		if label == SkipNode {
+			skip = true
+		} else {
+			if attrs == nil {
+				attrs = map[string]string{}
+			}
+			attrs["label"] = label
+			if shape != "" {
+				attrs["shape"] = shape
+			}
+			this.Nodes.Add(&Node{node.Name(), attrs})
+			this.Relations.Add(parentGraph, node.Name())
+		}
+	} else {
+		this.AddNodeInterface(parentGraph, node)
+	}
+	if skip {
+		//a NodeRenderer that excludes its node must also exclude that
+		//node's edges, matching Terraform dag's skip map: an edge
+		//referencing a node absent from Nodes/Relations is invalid.
+		return
+	}
+
+	if renderer, ok := node.(EdgeRenderer); ok {
+		for _, e := range renderer.DOTEdges(node.Name()) {
+			this.Edges.Add(e)
+		}
+	} else {
+		this.AddEdgesInterface(node)
+	}
 }
 
 func (this *Graph) AddGraphableNodes(parentGraph string, nodes []GraphableNode) {
@@ -184,11 +221,19 @@ func (this *Graph) IsSubGraph(name string) bool {
 
 //Creates an Abstract Syntrax Tree from the Graph.
 func (g *Graph) WriteAst() *ast.Graph {
+	restoreCycles := g.applyCycleAttrs()
+	defer restoreCycles()
+	restoreQuoting := g.applyQuoting()
+	defer restoreQuoting()
 	w := newWriter(g)
 	return w.Write()
 }
 
 //Returns a DOT string representing the Graph.
 func (g *Graph) String() string {
-	return g.WriteAst().String()
+	var buf bytes.Buffer
+	if _, err := g.WriteTo(&buf, nil); err != nil {
+		return g.WriteAst().String()
+	}
+	return buf.String()
 }
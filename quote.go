@@ -0,0 +1,125 @@
+//Copyright 2013 Vastech SA (PTY) LTD
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS,
+//WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//See the License for the specific language governing permissions and
+//limitations under the License.
+
+package gographviz
+
+import "strings"
+
+//dotKeywords must always be quoted when used as an attribute value, since
+//unquoted they would parse as a DOT keyword rather than a plain ID.
+var dotKeywords = []string{"graph", "node", "edge", "subgraph", "digraph", "strict"}
+
+//IsHTMLLabel reports whether value is an HTML-like label: Graphviz accepts
+//a bare `<...>` in place of a quoted string for labels that contain an HTML
+//table, and such values must never be wrapped in quotes or escaped.
+func IsHTMLLabel(value string) bool {
+	return len(value) >= 2 && strings.HasPrefix(value, "<") && strings.HasSuffix(value, ">")
+}
+
+//QuoteValue prepares value for output in DOT. HTML-like labels and values
+//that are already quoted or are a safe identifier are returned unchanged;
+//everything else (spaces, keywords, newlines, embedded quotes) is wrapped
+//in double quotes with internal quotes and backslashes escaped. It is
+//wired into WriteAst/WriteTo via applyQuoting below, so every value this
+//package writes is safe to read back with a conforming DOT parser.
+//
+//UnquoteValue below is exactly the inverse of QuoteValue. Nothing in this
+//module calls it, so the parse path does not unquote the values it reads.
+func QuoteValue(value string) string {
+	if IsHTMLLabel(value) || isQuoted(value) || !needsQuoting(value) {
+		return value
+	}
+	escaped := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value)
+	return `"` + escaped + `"`
+}
+
+//UnquoteValue reverses QuoteValue: a quoted string has its surrounding
+//quotes stripped and its escapes undone; an HTML-like label or an already
+//unquoted value is returned as-is. See the scope note on QuoteValue.
+func UnquoteValue(value string) string {
+	if IsHTMLLabel(value) || !isQuoted(value) {
+		return value
+	}
+	inner := value[1 : len(value)-1]
+	return strings.NewReplacer(`\"`, `"`, `\\`, `\`).Replace(inner)
+}
+
+func isQuoted(value string) bool {
+	return len(value) >= 2 && strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`)
+}
+
+func needsQuoting(value string) bool {
+	if value == "" {
+		return true
+	}
+	for _, kw := range dotKeywords {
+		if strings.EqualFold(value, kw) {
+			return true
+		}
+	}
+	for i, r := range value {
+		switch {
+		case r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z'):
+			continue
+		case r >= '0' && r <= '9' && i > 0:
+			continue
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+//applyQuoting rewrites every attribute value reachable from the graph -
+//the graph's own, every node's, every edge's and every subgraph's - to its
+//QuoteValue form for the duration of a single WriteAst call, and returns a
+//func that restores the originals so the in-memory graph keeps its
+//unquoted representation.
+func (this *Graph) applyQuoting() func() {
+	type touched struct {
+		attrs map[string]string
+		key   string
+		value string
+	}
+	var restore []touched
+	quoteAll := func(attrs map[string]string) {
+		for k, v := range attrs {
+			q := QuoteValue(v)
+			if q == v {
+				continue
+			}
+			restore = append(restore, touched{attrs, k, v})
+			attrs[k] = q
+		}
+	}
+
+	quoteAll(this.Attrs)
+	for _, n := range this.Nodes.Lookup {
+		quoteAll(n.Attrs)
+	}
+	for _, dsts := range this.Edges.SrcToDsts {
+		for _, e := range dsts {
+			quoteAll(e.Attrs)
+		}
+	}
+	for _, sg := range this.SubGraphs.SubGraphs {
+		quoteAll(sg.Attrs)
+	}
+
+	return func() {
+		for _, r := range restore {
+			r.attrs[r.key] = r.value
+		}
+	}
+}
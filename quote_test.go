@@ -0,0 +1,80 @@
+//Copyright 2013 Vastech SA (PTY) LTD
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS,
+//WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//See the License for the specific language governing permissions and
+//limitations under the License.
+
+package gographviz
+
+import "testing"
+
+func TestQuoteValueLeavesSafeValuesAlone(t *testing.T) {
+	for _, value := range []string{"a", "a_1", "node1", "_private"} {
+		if got := QuoteValue(value); got != value {
+			t.Errorf("QuoteValue(%q) = %q, want unchanged", value, got)
+		}
+	}
+}
+
+func TestQuoteValueQuotesUnsafeValues(t *testing.T) {
+	cases := map[string]string{
+		"has space":   `"has space"`,
+		"graph":       `"graph"`,
+		"1.0.0":       `"1.0.0"`,
+		"":            `""`,
+		`has "quote"`: `"has \"quote\""`,
+	}
+	for in, want := range cases {
+		if got := QuoteValue(in); got != want {
+			t.Errorf("QuoteValue(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestQuoteValuePreservesHTMLLabels(t *testing.T) {
+	html := "<<table><tr><td>a b</td></tr></table>>"
+	if got := QuoteValue(html); got != html {
+		t.Errorf("QuoteValue(%q) = %q, want unchanged", html, got)
+	}
+}
+
+func TestQuoteUnquoteRoundTrip(t *testing.T) {
+	values := []string{
+		"a",
+		"has space",
+		"graph",
+		`has "quote"`,
+		`has \ backslash`,
+		"<<table></table>>",
+		"",
+	}
+	for _, value := range values {
+		quoted := QuoteValue(value)
+		if got := UnquoteValue(quoted); got != value {
+			t.Errorf("UnquoteValue(QuoteValue(%q)) = %q, want %q", value, got, value)
+		}
+	}
+}
+
+func TestApplyQuotingRestoresOriginalAttrs(t *testing.T) {
+	g := NewGraph()
+	g.SetName("g")
+	g.AddNode("g", "a", map[string]string{"label": "has space"})
+
+	restore := g.applyQuoting()
+	if got := g.Nodes.Lookup["a"].Attrs["label"]; got != `"has space"` {
+		t.Fatalf("expected label to be quoted during render, got %q", got)
+	}
+	restore()
+	if got := g.Nodes.Lookup["a"].Attrs["label"]; got != "has space" {
+		t.Fatalf("expected label to be restored after render, got %q", got)
+	}
+}
@@ -0,0 +1,37 @@
+//Copyright 2013 Vastech SA (PTY) LTD
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS,
+//WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//See the License for the specific language governing permissions and
+//limitations under the License.
+
+package gographviz
+
+//SkipNode is returned as the label by a NodeRenderer that wants its node
+//excluded from the rendered graph entirely, matching Terraform dag's skip
+//map behavior.
+const SkipNode = "\x00gographviz-skip-node\x00"
+
+//NodeRenderer lets a user type control how it is emitted into the AST when
+//passed to AddGraphableNode, instead of being flattened into a plain
+//map[string]string via NodeInterface. This mirrors Terraform's
+//dag.GraphNodeDotter: implement it to own rich labels (record shapes, HTML
+//tables, cluster hints) without pre-flattening into attrs yourself.
+type NodeRenderer interface {
+	//DOTNode returns the label, attrs and shape to render name as.
+	//Returning SkipNode as the label excludes the node from the output.
+	DOTNode(name string) (label string, attrs map[string]string, shape string)
+}
+
+//EdgeRenderer lets a user type control how its outgoing edges are emitted,
+//in place of EdgesInterface, when passed to AddGraphableNode.
+type EdgeRenderer interface {
+	DOTEdges(name string) []*Edge
+}
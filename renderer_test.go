@@ -0,0 +1,147 @@
+//Copyright 2013 Vastech SA (PTY) LTD
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS,
+//WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//See the License for the specific language governing permissions and
+//limitations under the License.
+
+package gographviz
+
+import "testing"
+
+//plainNode is a GraphableNode with no NodeRenderer/EdgeRenderer, so
+//AddGraphableNode must fall back to AddNodeInterface/AddEdgesInterface.
+type plainNode struct {
+	name  string
+	attrs map[string]string
+	edges []*Edge
+}
+
+func (n *plainNode) Name() string   { return n.name }
+func (n *plainNode) Attrs() Attrs   { return n.attrs }
+func (n *plainNode) Edges() []*Edge { return n.edges }
+
+//renderedNode is a GraphableNode that also implements NodeRenderer, and
+//optionally EdgeRenderer, to exercise AddGraphableNode's dispatch.
+type renderedNode struct {
+	plainNode
+	label    string
+	dotAttrs map[string]string
+	shape    string
+	dotEdges []*Edge
+}
+
+func (n *renderedNode) DOTNode(name string) (string, map[string]string, string) {
+	return n.label, n.dotAttrs, n.shape
+}
+
+func (n *renderedNode) DOTEdges(name string) []*Edge {
+	return n.dotEdges
+}
+
+//edgeRendererNode only implements EdgeRenderer, not NodeRenderer, to
+//exercise that dispatch independently.
+type edgeRendererNode struct {
+	plainNode
+	dotEdges []*Edge
+}
+
+func (n *edgeRendererNode) DOTEdges(name string) []*Edge {
+	return n.dotEdges
+}
+
+func TestAddGraphableNodePlainFallsBackToInterfaces(t *testing.T) {
+	g := NewGraph()
+	g.SetName("g")
+	g.SetDir(true)
+	g.AddNode("g", "b", nil)
+	n := &plainNode{
+		name:  "a",
+		attrs: map[string]string{"color": "blue"},
+		edges: []*Edge{{Src: "a", Dst: "b", Dir: true}},
+	}
+
+	g.AddGraphableNode("g", n)
+
+	if !g.IsNode("a") {
+		t.Fatalf("expected node a to be added")
+	}
+	if g.Nodes.Lookup["a"].Attrs["color"] != "blue" {
+		t.Fatalf("expected plain attrs to be used unchanged, got %v", g.Nodes.Lookup["a"].Attrs)
+	}
+	if _, ok := g.Edges.SrcToDsts["a"]["b"]; !ok {
+		t.Fatalf("expected edge a->b to be added via EdgesInterface")
+	}
+}
+
+func TestAddGraphableNodeRendererSetsLabelAndShape(t *testing.T) {
+	g := NewGraph()
+	g.SetName("g")
+	g.SetDir(true)
+	g.AddNode("g", "b", nil)
+	n := &renderedNode{
+		plainNode: plainNode{name: "a"},
+		label:     "A",
+		dotAttrs:  map[string]string{"color": "red"},
+		shape:     "box",
+		dotEdges:  []*Edge{{Src: "a", Dst: "b", Dir: true}},
+	}
+
+	g.AddGraphableNode("g", n)
+
+	attrs := g.Nodes.Lookup["a"].Attrs
+	if attrs["label"] != "A" || attrs["shape"] != "box" || attrs["color"] != "red" {
+		t.Fatalf("expected label/shape/color to be set from DOTNode, got %v", attrs)
+	}
+	if _, ok := g.Edges.SrcToDsts["a"]["b"]; !ok {
+		t.Fatalf("expected edge a->b to be added via DOTEdges")
+	}
+}
+
+func TestAddGraphableNodeSkipNodeAlsoSkipsEdges(t *testing.T) {
+	g := NewGraph()
+	g.SetName("g")
+	g.SetDir(true)
+	g.AddNode("g", "b", nil)
+	n := &renderedNode{
+		plainNode: plainNode{name: "a"},
+		label:     SkipNode,
+		dotEdges:  []*Edge{{Src: "a", Dst: "b", Dir: true}},
+	}
+
+	g.AddGraphableNode("g", n)
+
+	if g.IsNode("a") {
+		t.Fatalf("expected node a to be excluded")
+	}
+	if _, ok := g.Edges.SrcToDsts["a"]; ok {
+		t.Fatalf("expected a's edges to be excluded along with the node, got %v", g.Edges.SrcToDsts["a"])
+	}
+}
+
+func TestAddGraphableNodeEdgeRendererWithoutNodeRenderer(t *testing.T) {
+	g := NewGraph()
+	g.SetName("g")
+	g.SetDir(true)
+	g.AddNode("g", "b", nil)
+	n := &edgeRendererNode{
+		plainNode: plainNode{name: "a", attrs: map[string]string{"color": "green"}},
+		dotEdges:  []*Edge{{Src: "a", Dst: "b", Dir: true}},
+	}
+
+	g.AddGraphableNode("g", n)
+
+	if g.Nodes.Lookup["a"].Attrs["color"] != "green" {
+		t.Fatalf("expected plain attrs via AddNodeInterface, got %v", g.Nodes.Lookup["a"].Attrs)
+	}
+	if _, ok := g.Edges.SrcToDsts["a"]["b"]; !ok {
+		t.Fatalf("expected edge a->b to be added via DOTEdges")
+	}
+}
@@ -0,0 +1,187 @@
+//Copyright 2013 Vastech SA (PTY) LTD
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS,
+//WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//See the License for the specific language governing permissions and
+//limitations under the License.
+
+package gographviz
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+)
+
+//WriteTo emits this graph as DOT directly to w, without first
+//materializing the full AST via WriteAst().String(). For graphs with
+//hundreds of thousands of nodes and edges that avoids allocating the whole
+//AST plus a giant string in memory. Nodes, subgraphs and edges are written
+//in a deterministic, sorted order, so two writes of an unchanged graph are
+//byte-for-byte identical. opts may be nil.
+func (g *Graph) WriteTo(w io.Writer, opts *WriteOpts) (int64, error) {
+	if opts != nil && (opts.MaxDepth > 0 || opts.CollapseBelow != nil) {
+		g = g.collapse(opts)
+	}
+
+	cw := &countingWriter{w: bufio.NewWriter(w)}
+	g.streamTo(cw)
+	err := cw.w.Flush()
+	return cw.n, err
+}
+
+func (g *Graph) streamTo(w *countingWriter) {
+	restoreCycles := g.applyCycleAttrs()
+	defer restoreCycles()
+	restoreQuoting := g.applyQuoting()
+	defer restoreQuoting()
+
+	conn := "--"
+	if g.Strict {
+		w.writeString("strict ")
+	}
+	if g.Directed {
+		conn = "->"
+		w.writeString("digraph ")
+	} else {
+		w.writeString("graph ")
+	}
+	w.writeString(SafeName(g.Name))
+	w.writeString(" {\n")
+
+	writeAttrs(w, g.Attrs)
+
+	//written tracks every subgraph already emitted, whether inline as an
+	//edge endpoint or on its own, and every node whose statement (with its
+	//attrs) has been written, mirroring
+	//writer.writtenLocations in WriteAst: a node that belongs to a
+	//subgraph must have its attrs printed once, inside that subgraph's
+	//block, not a second time as a bare top-level statement.
+	written := make(map[string]bool)
+	for _, e := range g.Edges.Sorted() {
+		g.streamEdge(w, conn, e, written)
+	}
+	for _, sg := range g.SubGraphs.Sorted() {
+		if !written[sg.Name] {
+			g.streamSubgraph(w, sg, written)
+		}
+	}
+	for _, n := range g.Nodes.Sorted() {
+		if !written[n.Name] {
+			g.streamNode(w, n, written)
+		}
+	}
+
+	w.writeString("}\n")
+}
+
+//streamEdge writes one edge statement. An endpoint that names a subgraph
+//rather than a node has that subgraph's whole block inlined in its place,
+//matching the compound-edge behaviour of writer.newLocation in WriteAst.
+func (g *Graph) streamEdge(w *countingWriter, conn string, e *Edge, written map[string]bool) {
+	g.streamLocation(w, e.Src, e.SrcPort, written)
+	w.writeString(" ")
+	w.writeString(conn)
+	w.writeString(" ")
+	g.streamLocation(w, e.Dst, e.DstPort, written)
+	writeAttrList(w, e.Attrs)
+	w.writeString(";\n")
+}
+
+//streamLocation writes name, with port if given, as it appears on one side
+//of an edge statement. A subgraph cannot carry a port. A node written here
+//is not marked as written, so its own statement, with its attrs, still
+//follows later.
+func (g *Graph) streamLocation(w *countingWriter, name, port string, written map[string]bool) {
+	if g.IsSubGraph(name) {
+		if port != "" {
+			panic(fmt.Sprintf("subgraph cannot have a port: %v", port))
+		}
+		g.streamSubgraph(w, g.SubGraphs.SubGraphs[name], written)
+		return
+	}
+	w.writeString(SafeName(name))
+	if port != "" {
+		w.writeString(":")
+		w.writeString(SafeName(port))
+	}
+}
+
+//streamSubgraph writes sg as a "subgraph name { ... }" block holding its
+//own attrs and every member node, sourced from Relations.SortedChildren -
+//subgraphs never nest, so this is always exactly one level deep - and
+//marks sg and its members as written.
+func (g *Graph) streamSubgraph(w *countingWriter, sg *SubGraph, written map[string]bool) {
+	written[sg.Name] = true
+	w.writeString("subgraph ")
+	w.writeString(SafeName(sg.Name))
+	w.writeString(" {\n")
+	writeAttrs(w, sg.Attrs)
+	for _, child := range g.Relations.SortedChildren(sg.Name) {
+		g.streamNode(w, g.Nodes.Lookup[child], written)
+	}
+	w.writeString("}\n")
+}
+
+func (g *Graph) streamNode(w *countingWriter, n *Node, written map[string]bool) {
+	written[n.Name] = true
+	w.writeString(SafeName(n.Name))
+	writeAttrList(w, n.Attrs)
+	w.writeString(";\n")
+}
+
+func writeAttrs(w *countingWriter, attrs map[string]string) {
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		w.writeString(k)
+		w.writeString("=")
+		w.writeString(attrs[k])
+		w.writeString(";\n")
+	}
+}
+
+func writeAttrList(w *countingWriter, attrs map[string]string) {
+	if len(attrs) == 0 {
+		return
+	}
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	w.writeString(" [")
+	for i, k := range keys {
+		if i > 0 {
+			w.writeString(", ")
+		}
+		w.writeString(k)
+		w.writeString("=")
+		w.writeString(attrs[k])
+	}
+	w.writeString("]")
+}
+
+//countingWriter tracks bytes written so WriteTo can report its
+//io.WriterTo-style (int64, error) return without a second pass over the
+//output.
+type countingWriter struct {
+	w *bufio.Writer
+	n int64
+}
+
+func (c *countingWriter) writeString(s string) {
+	n, _ := c.w.WriteString(s)
+	c.n += int64(n)
+}
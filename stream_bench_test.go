@@ -0,0 +1,56 @@
+//Copyright 2013 Vastech SA (PTY) LTD
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//Unless required by applicable law or agreed to in writing, software
+//distributed under the License is distributed on an "AS IS" BASIS,
+//WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//See the License for the specific language governing permissions and
+//limitations under the License.
+
+package gographviz
+
+import (
+	"io/ioutil"
+	"strconv"
+	"testing"
+)
+
+//benchGraph builds a synthetic graph with numNodes nodes, each connected to
+//the next ten, for roughly numNodes*10 edges.
+func benchGraph(numNodes int) *Graph {
+	g := NewGraph()
+	g.SetName("bench")
+	g.SetDir(true)
+	for i := 0; i < numNodes; i++ {
+		g.AddNode("bench", "n"+strconv.Itoa(i), nil)
+	}
+	for i := 0; i < numNodes; i++ {
+		for j := 1; j <= 10; j++ {
+			g.AddEdge("n"+strconv.Itoa(i), "n"+strconv.Itoa((i+j)%numNodes), true, nil)
+		}
+	}
+	return g
+}
+
+func BenchmarkWriteAstString(b *testing.B) {
+	g := benchGraph(10000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = g.WriteAst().String()
+	}
+}
+
+func BenchmarkWriteTo(b *testing.B) {
+	g := benchGraph(10000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := g.WriteTo(ioutil.Discard, nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}